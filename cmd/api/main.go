@@ -1,10 +1,15 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"log"
-	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/jamie-at-bunny/mc-go-test/internal/api"
 )
 
 func main() {
@@ -14,21 +19,59 @@ func main() {
 	}
 
 	redisURL := os.Getenv("REDIS_URL")
+	if redisURL == "" {
+		redisURL = "redis://localhost:6379/0"
+	}
+
+	grace := api.DefaultShutdownGrace
+	if s := os.Getenv("SHUTDOWN_GRACE_PERIOD"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			log.Fatalf("API: invalid SHUTDOWN_GRACE_PERIOD %q: %v", s, err)
+		}
+		grace = d
+	}
+
+	rateLimitRequests := api.DefaultRateLimitRequests
+	if s := os.Getenv("RATE_LIMIT_REQUESTS"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			log.Fatalf("API: invalid RATE_LIMIT_REQUESTS %q: %v", s, err)
+		}
+		rateLimitRequests = n
+	}
 
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprintf(w, "Hello from the Go API!\n")
-	})
+	rateLimitWindow := api.DefaultRateLimitWindow
+	if s := os.Getenv("RATE_LIMIT_WINDOW"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			log.Fatalf("API: invalid RATE_LIMIT_WINDOW %q: %v", s, err)
+		}
+		rateLimitWindow = d
+	}
 
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprintf(w, "ok\n")
-	})
+	rateLimitFailClosed := false
+	if s := os.Getenv("RATE_LIMIT_FAIL_CLOSED"); s != "" {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			log.Fatalf("API: invalid RATE_LIMIT_FAIL_CLOSED %q: %v", s, err)
+		}
+		rateLimitFailClosed = b
+	}
 
-	http.HandleFunc("/enqueue", func(w http.ResponseWriter, r *http.Request) {
-		// In a real app this would push a job to Redis
-		fmt.Fprintf(w, "Job enqueued (redis: %s)\n", redisURL)
-	})
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
 
-	log.Printf("API starting on :%s (redis: %s)", port, redisURL)
-	log.Fatal(http.ListenAndServe(":"+port, nil))
+	cfg := api.Config{
+		Port:                port,
+		RedisURL:            redisURL,
+		ShutdownGrace:       grace,
+		RateLimitRequests:   rateLimitRequests,
+		RateLimitWindow:     rateLimitWindow,
+		RateLimitFailClosed: rateLimitFailClosed,
+		OTELEndpoint:        os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+	}
+	if err := api.Run(ctx, cfg); err != nil {
+		log.Fatalf("API: %v", err)
+	}
 }
@@ -1,31 +1,35 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
+
+	"github.com/jamie-at-bunny/mc-go-test/internal/worker"
 )
 
 func main() {
 	redisURL := os.Getenv("REDIS_URL")
-	log.Printf("Worker starting (redis: %s)", redisURL)
+	if redisURL == "" {
+		redisURL = "redis://localhost:6379/0"
+	}
 
-	// Simulate a worker loop that processes jobs from Redis
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
+	metricsPort := os.Getenv("METRICS_PORT")
+	if metricsPort == "" {
+		metricsPort = "9091"
+	}
 
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
 
-	for {
-		select {
-		case <-ticker.C:
-			log.Println("Worker: checking for jobs...")
-		case <-stop:
-			log.Println("Worker: shutting down")
-			return
-		}
+	cfg := worker.Config{
+		RedisURL:     redisURL,
+		OTELEndpoint: os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		MetricsPort:  metricsPort,
+	}
+	if err := worker.Run(ctx, cfg); err != nil {
+		log.Fatalf("Worker: %v", err)
 	}
 }
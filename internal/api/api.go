@@ -0,0 +1,271 @@
+// Package api implements the HTTP server for the api binary as a
+// Run(ctx, cfg) function so it can be started and stopped from tests as
+// well as from cmd/api/main.go.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	otelpkg "github.com/jamie-at-bunny/mc-go-test/internal/otel"
+	"github.com/jamie-at-bunny/mc-go-test/internal/queue"
+	"github.com/jamie-at-bunny/mc-go-test/internal/ratelimit"
+)
+
+// DefaultShutdownGrace is used when Config.ShutdownGrace is zero.
+const DefaultShutdownGrace = 10 * time.Second
+
+// Defaults applied to the /enqueue rate limiter when the corresponding
+// Config field is unset.
+const (
+	DefaultRateLimitRequests = 60
+	DefaultRateLimitWindow   = time.Minute
+)
+
+// Config holds the environment-derived settings the API needs to run.
+type Config struct {
+	// Port the server listens on. "0" binds an ephemeral port, which is
+	// useful in tests; OnReady reports the address that was bound.
+	Port     string
+	RedisURL string
+
+	// ShutdownGrace bounds how long Run waits for in-flight requests to
+	// drain after ctx is cancelled before forcibly closing the server.
+	// Defaults to DefaultShutdownGrace.
+	ShutdownGrace time.Duration
+
+	// RateLimitRequests and RateLimitWindow bound how many requests to
+	// /enqueue a single client may make per window. Defaults to
+	// DefaultRateLimitRequests per DefaultRateLimitWindow.
+	RateLimitRequests int
+	RateLimitWindow   time.Duration
+
+	// RateLimitFailClosed controls what happens to /enqueue when Redis is
+	// unreachable for a rate-limit check: false (the default) lets the
+	// request through (fail-open), true rejects it with 503 (fail-closed).
+	RateLimitFailClosed bool
+
+	// OTELEndpoint is the OTLP/gRPC endpoint traces are exported to,
+	// typically from OTEL_EXPORTER_OTLP_ENDPOINT. Empty disables trace
+	// export; /metrics is served either way.
+	OTELEndpoint string
+
+	// OnReady, if set, is called with the bound listener address once the
+	// server is ready to accept connections, before Run starts blocking.
+	OnReady func(addr string)
+}
+
+type enqueueRequest struct {
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Run starts the API server and blocks until ctx is cancelled, draining
+// in-flight requests for up to cfg.ShutdownGrace before returning.
+func Run(ctx context.Context, cfg Config) error {
+	grace := cfg.ShutdownGrace
+	if grace <= 0 {
+		grace = DefaultShutdownGrace
+	}
+
+	rateLimitRequests := cfg.RateLimitRequests
+	if rateLimitRequests <= 0 {
+		rateLimitRequests = DefaultRateLimitRequests
+	}
+	rateLimitWindow := cfg.RateLimitWindow
+	if rateLimitWindow <= 0 {
+		rateLimitWindow = DefaultRateLimitWindow
+	}
+
+	telemetry, err := otelpkg.Setup(ctx, otelpkg.Config{ServiceName: "mc-go-test-api", Endpoint: cfg.OTELEndpoint})
+	if err != nil {
+		return fmt.Errorf("api: setting up telemetry: %w", err)
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := telemetry.Shutdown(shutdownCtx); err != nil {
+			log.Printf("API: telemetry shutdown: %v", err)
+		}
+	}()
+
+	jobMetrics, err := otelpkg.NewJobMetrics(telemetry.MeterProvider)
+	if err != nil {
+		return fmt.Errorf("api: setting up job metrics: %w", err)
+	}
+
+	client, err := queue.NewClient(cfg.RedisURL)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if err := otelpkg.InstrumentRedis(client, telemetry.TracerProvider, telemetry.MeterProvider); err != nil {
+		return fmt.Errorf("api: instrumenting redis client: %w", err)
+	}
+	q := queue.New(client)
+
+	var shuttingDown atomic.Bool
+
+	mux := http.NewServeMux()
+	handle := func(pattern, spanName string, handler http.HandlerFunc) {
+		mux.Handle(pattern, otelhttp.NewHandler(handler, spanName, otelhttp.WithTracerProvider(telemetry.TracerProvider)))
+	}
+
+	handle("/", "root", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "Hello from the Go API!\n")
+	})
+
+	// /livez reports whether the process is up; orchestrators use it to
+	// decide whether to restart the container.
+	handle("/livez", "livez", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "ok\n")
+	})
+
+	// /readyz reports whether the API is ready to receive traffic; it fails
+	// once shutdown has begun or Redis is unreachable, so orchestrators can
+	// stop routing new requests here during rollouts.
+	handle("/readyz", "readyz", func(w http.ResponseWriter, r *http.Request) {
+		if shuttingDown.Load() {
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		if err := client.Ping(r.Context()).Err(); err != nil {
+			http.Error(w, fmt.Sprintf("redis unhealthy: %v", err), http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "ok\n")
+	})
+
+	mux.Handle("/metrics", telemetry.MetricsHandler)
+
+	limiter := ratelimit.New(client, rateLimitRequests, rateLimitWindow, !cfg.RateLimitFailClosed)
+	handle("/enqueue", "enqueue", limiter.Middleware(ratelimit.KeyByAPIKeyOrIP, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req enqueueRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+		if req.Type == "" {
+			http.Error(w, "type is required", http.StatusBadRequest)
+			return
+		}
+
+		traceParent := otelpkg.InjectTraceParent(r.Context())
+		job, err := q.EnqueueWithTraceParent(r.Context(), req.Type, req.Payload, traceParent)
+		if err != nil {
+			log.Printf("API: enqueue failed: %v", err)
+			http.Error(w, "failed to enqueue job", http.StatusInternalServerError)
+			return
+		}
+		jobMetrics.Enqueued.Add(r.Context(), 1)
+		if err := q.Publish(r.Context(), queue.Event{JobID: job.ID, Type: queue.EventQueued}); err != nil {
+			log.Printf("API: publishing queued event for job %s: %v", job.ID, err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(job)
+	})).ServeHTTP)
+
+	handle("GET /jobs/{id}/events", "jobs.events.byID", jobEventsHandler(q, true))
+	handle("GET /jobs/events", "jobs.events.firehose", jobEventsHandler(q, false))
+
+	ln, err := net.Listen("tcp", ":"+cfg.Port)
+	if err != nil {
+		return fmt.Errorf("api: listening on port %s: %w", cfg.Port, err)
+	}
+
+	log.Printf("API starting on %s (redis: %s)", ln.Addr(), cfg.RedisURL)
+	if cfg.OnReady != nil {
+		cfg.OnReady(ln.Addr().String())
+	}
+
+	srv := &http.Server{Handler: mux}
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- srv.Serve(ln) }()
+
+	select {
+	case <-ctx.Done():
+		shuttingDown.Store(true)
+		log.Printf("API: shutting down, draining for up to %s", grace)
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), grace)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("API: shutdown did not drain cleanly: %v", err)
+			srv.Close()
+		}
+		<-serveErr
+		return nil
+	case err := <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	}
+}
+
+// jobEventsHandler streams queue.Events to the client as Server-Sent
+// Events. When filterByID is true, it streams only events for the job
+// named by the {id} path value; otherwise it's an admin firehose of every
+// job's events.
+func jobEventsHandler(q *queue.Queue, filterByID bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		jobID := r.PathValue("id")
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		events, closeSub := q.Subscribe(ctx)
+		defer closeSub()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if filterByID && event.JobID != jobID {
+					continue
+				}
+				data, err := json.Marshal(event)
+				if err != nil {
+					log.Printf("API: marshaling event for job %s: %v", event.JobID, err)
+					continue
+				}
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+				flusher.Flush()
+			}
+		}
+	}
+}
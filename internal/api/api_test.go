@@ -0,0 +1,210 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamie-at-bunny/mc-go-test/internal/queue"
+	"github.com/jamie-at-bunny/mc-go-test/internal/testredis"
+)
+
+func TestReadyzAndLivez(t *testing.T) {
+	redisURL := fmt.Sprintf("redis://%s/0", testredis.Start(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ready := make(chan string, 1)
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- Run(ctx, Config{
+			Port:     "0",
+			RedisURL: redisURL,
+			OnReady:  func(addr string) { ready <- addr },
+		})
+	}()
+
+	var addr string
+	select {
+	case addr = <-ready:
+	case err := <-runErr:
+		t.Fatalf("api exited before becoming ready: %v", err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for api to start")
+	}
+
+	resp, err := http.Get(fmt.Sprintf("http://%s/livez", addr))
+	if err != nil {
+		t.Fatalf("GET /livez: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("/livez returned %d, want 200", resp.StatusCode)
+	}
+
+	resp, err = http.Get(fmt.Sprintf("http://%s/readyz", addr))
+	if err != nil {
+		t.Fatalf("GET /readyz: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("/readyz returned %d, want 200", resp.StatusCode)
+	}
+
+	cancel()
+
+	select {
+	case err := <-runErr:
+		if err != nil {
+			t.Fatalf("Run returned error on shutdown: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for Run to return after shutdown")
+	}
+}
+
+// TestJobEventsStreamFiltersByID enqueues two jobs, opens a filtered SSE
+// stream for one of them, and confirms only that job's events arrive even
+// while the other job's events are being published concurrently. It also
+// exercises a client disconnect, confirming Run still shuts down cleanly
+// afterward.
+func TestJobEventsStreamFiltersByID(t *testing.T) {
+	redisURL := fmt.Sprintf("redis://%s/0", testredis.Start(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ready := make(chan string, 1)
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- Run(ctx, Config{
+			Port:     "0",
+			RedisURL: redisURL,
+			OnReady:  func(addr string) { ready <- addr },
+		})
+	}()
+
+	var addr string
+	select {
+	case addr = <-ready:
+	case err := <-runErr:
+		t.Fatalf("api exited before becoming ready: %v", err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for api to start")
+	}
+
+	enqueue := func() string {
+		resp, err := http.Post(fmt.Sprintf("http://%s/enqueue", addr), "application/json", strings.NewReader(`{"type":"noop"}`))
+		if err != nil {
+			t.Fatalf("POST /enqueue: %v", err)
+		}
+		defer resp.Body.Close()
+		var job struct {
+			ID string `json:"id"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+			t.Fatalf("decoding enqueue response: %v", err)
+		}
+		return job.ID
+	}
+
+	jobA := enqueue()
+	jobB := enqueue()
+
+	streamCtx, streamCancel := context.WithCancel(context.Background())
+	defer streamCancel()
+	req, err := http.NewRequestWithContext(streamCtx, http.MethodGet, fmt.Sprintf("http://%s/jobs/%s/events", addr, jobA), nil)
+	if err != nil {
+		t.Fatalf("building request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /jobs/{id}/events: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("/jobs/{id}/events returned %d, want 200", resp.StatusCode)
+	}
+
+	client, err := queue.NewClient(redisURL)
+	if err != nil {
+		t.Fatalf("queue.NewClient: %v", err)
+	}
+	defer client.Close()
+	q := queue.New(client)
+
+	// Subscribe is established before the handler writes its response
+	// headers, but keep publishing both jobs' events for a bit so the
+	// test doesn't depend on winning a single race against the
+	// subscription reaching Redis.
+	stopPublishing := make(chan struct{})
+	defer close(stopPublishing)
+	go func() {
+		ticker := time.NewTicker(20 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopPublishing:
+				return
+			case <-ticker.C:
+				q.Publish(context.Background(), queue.Event{JobID: jobB, Type: queue.EventStarted})
+				q.Publish(context.Background(), queue.Event{JobID: jobA, Type: queue.EventStarted})
+			}
+		}
+	}()
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	var sawJobA, sawJobB bool
+	deadline := time.After(2 * time.Second)
+readLoop:
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				break readLoop
+			}
+			if strings.Contains(line, jobB) {
+				sawJobB = true
+			}
+			if strings.Contains(line, jobA) {
+				sawJobA = true
+				break readLoop
+			}
+		case <-deadline:
+			break readLoop
+		}
+	}
+
+	if !sawJobA {
+		t.Fatal("timed out waiting for job A's event on its filtered stream")
+	}
+	if sawJobB {
+		t.Fatal("received job B's event on a stream filtered to job A")
+	}
+
+	streamCancel()
+	cancel()
+
+	select {
+	case err := <-runErr:
+		if err != nil {
+			t.Fatalf("Run returned error on shutdown: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for Run to return after shutdown")
+	}
+}
@@ -0,0 +1,105 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamie-at-bunny/mc-go-test/internal/api"
+	"github.com/jamie-at-bunny/mc-go-test/internal/queue"
+	"github.com/jamie-at-bunny/mc-go-test/internal/testredis"
+	"github.com/jamie-at-bunny/mc-go-test/internal/worker"
+)
+
+func TestEnqueueIsDrainedAndProcessedByWorker(t *testing.T) {
+	redisURL := fmt.Sprintf("redis://%s/0", testredis.Start(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ready := make(chan string, 1)
+	apiErrs := make(chan error, 1)
+	go func() {
+		apiErrs <- api.Run(ctx, api.Config{
+			Port:     "0",
+			RedisURL: redisURL,
+			OnReady:  func(addr string) { ready <- addr },
+		})
+	}()
+
+	var apiAddr string
+	select {
+	case apiAddr = <-ready:
+	case err := <-apiErrs:
+		t.Fatalf("api exited before becoming ready: %v", err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for api to start")
+	}
+
+	processed := make(chan string, 1)
+	worker.Handlers["integration-test"] = func(job *queue.Job) error {
+		processed <- job.ID
+		return nil
+	}
+	defer delete(worker.Handlers, "integration-test")
+
+	workerErrs := make(chan error, 1)
+	go func() {
+		workerErrs <- worker.Run(ctx, worker.Config{RedisURL: redisURL})
+	}()
+
+	resp, err := http.Post(
+		fmt.Sprintf("http://%s/enqueue", apiAddr),
+		"application/json",
+		strings.NewReader(`{"type":"integration-test","payload":{"n":1}}`),
+	)
+	if err != nil {
+		t.Fatalf("posting to /enqueue: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("enqueue returned %d, want 200", resp.StatusCode)
+	}
+
+	var job queue.Job
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		t.Fatalf("decoding enqueue response: %v", err)
+	}
+
+	select {
+	case id := <-processed:
+		if id != job.ID {
+			t.Fatalf("worker processed job %s, want %s", id, job.ID)
+		}
+	case err := <-workerErrs:
+		t.Fatalf("worker exited before processing job: %v", err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for worker to process job")
+	}
+
+	client, err := queue.NewClient(redisURL)
+	if err != nil {
+		t.Fatalf("connecting verification client: %v", err)
+	}
+	defer client.Close()
+
+	pending, err := client.LLen(ctx, queue.DefaultPendingKey).Result()
+	if err != nil {
+		t.Fatalf("checking pending list: %v", err)
+	}
+	if pending != 0 {
+		t.Fatalf("pending list has %d jobs, want 0", pending)
+	}
+
+	processing, err := client.LLen(ctx, queue.DefaultProcessingKey).Result()
+	if err != nil {
+		t.Fatalf("checking processing list: %v", err)
+	}
+	if processing != 0 {
+		t.Fatalf("processing list has %d jobs, want 0", processing)
+	}
+}
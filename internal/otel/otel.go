@@ -0,0 +1,180 @@
+// Package otel centralizes OpenTelemetry setup shared by the api and
+// worker binaries: an OTLP trace exporter, a Prometheus-scrapable meter
+// provider, job throughput metrics, and W3C trace-context propagation so a
+// trace can span an HTTP request and the job it eventually enqueues.
+package otel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/extra/redisotel/v9"
+	goredis "github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Config controls OTLP exporter and resource setup.
+type Config struct {
+	// ServiceName identifies this process in exported telemetry.
+	ServiceName string
+
+	// Endpoint is the OTLP/gRPC endpoint traces are pushed to, typically
+	// read from OTEL_EXPORTER_OTLP_ENDPOINT. An empty Endpoint disables
+	// trace export; Prometheus metrics remain available either way.
+	Endpoint string
+}
+
+// Providers bundles the installed tracer/meter providers, an HTTP handler
+// for Prometheus to scrape, and a teardown func.
+type Providers struct {
+	TracerProvider trace.TracerProvider
+	MeterProvider  metric.MeterProvider
+	MetricsHandler http.Handler
+	Shutdown       func(context.Context) error
+}
+
+// Setup installs a TracerProvider exporting to cfg.Endpoint over
+// OTLP/gRPC (a provider with no exporter if cfg.Endpoint is empty), a
+// MeterProvider that always serves Prometheus-format metrics via
+// Providers.MetricsHandler, and a W3C trace-context propagator as the
+// process-wide default.
+func Setup(ctx context.Context, cfg Config) (*Providers, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceNameKey.String(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("otel: building resource: %w", err)
+	}
+
+	registry := prometheus.NewRegistry()
+	promExporter, err := otelprometheus.New(otelprometheus.WithRegisterer(registry))
+	if err != nil {
+		return nil, fmt.Errorf("otel: creating prometheus exporter: %w", err)
+	}
+
+	meterProvider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(promExporter),
+		sdkmetric.WithResource(res),
+	)
+	otel.SetMeterProvider(meterProvider)
+
+	var tracerProvider trace.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithResource(res))
+	shutdownFuncs := []func(context.Context) error{meterProvider.Shutdown}
+
+	if cfg.Endpoint != "" {
+		traceExporter, err := otlptracegrpc.New(ctx,
+			otlptracegrpc.WithEndpoint(cfg.Endpoint),
+			otlptracegrpc.WithInsecure(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("otel: creating trace exporter: %w", err)
+		}
+		sdkTracerProvider := sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(traceExporter),
+			sdktrace.WithResource(res),
+		)
+		tracerProvider = sdkTracerProvider
+		shutdownFuncs = append(shutdownFuncs, sdkTracerProvider.Shutdown)
+	}
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return &Providers{
+		TracerProvider: tracerProvider,
+		MeterProvider:  meterProvider,
+		MetricsHandler: promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+		Shutdown: func(shutdownCtx context.Context) error {
+			for _, fn := range shutdownFuncs {
+				if err := fn(shutdownCtx); err != nil {
+					return fmt.Errorf("otel: shutting down: %w", err)
+				}
+			}
+			return nil
+		},
+	}, nil
+}
+
+// InstrumentRedis wraps client with OTel tracing and metrics instrumentation.
+func InstrumentRedis(client *goredis.Client, tp trace.TracerProvider, mp metric.MeterProvider) error {
+	if err := redisotel.InstrumentTracing(client, redisotel.WithTracerProvider(tp)); err != nil {
+		return fmt.Errorf("otel: instrumenting redis tracing: %w", err)
+	}
+	if err := redisotel.InstrumentMetrics(client, redisotel.WithMeterProvider(mp)); err != nil {
+		return fmt.Errorf("otel: instrumenting redis metrics: %w", err)
+	}
+	return nil
+}
+
+// JobMetrics holds the counters and histogram tracking job throughput,
+// exposed on /metrics alongside the Redis client and HTTP instrumentation.
+type JobMetrics struct {
+	Enqueued  metric.Int64Counter
+	Processed metric.Int64Counter
+	Failed    metric.Int64Counter
+	Duration  metric.Float64Histogram
+}
+
+// NewJobMetrics creates the job counters and histogram against mp.
+func NewJobMetrics(mp metric.MeterProvider) (*JobMetrics, error) {
+	meter := mp.Meter("github.com/jamie-at-bunny/mc-go-test")
+
+	enqueued, err := meter.Int64Counter("jobs.enqueued",
+		metric.WithDescription("Number of jobs enqueued"))
+	if err != nil {
+		return nil, fmt.Errorf("otel: creating jobs.enqueued counter: %w", err)
+	}
+	processed, err := meter.Int64Counter("jobs.processed",
+		metric.WithDescription("Number of jobs processed successfully"))
+	if err != nil {
+		return nil, fmt.Errorf("otel: creating jobs.processed counter: %w", err)
+	}
+	failed, err := meter.Int64Counter("jobs.failed",
+		metric.WithDescription("Number of jobs that failed processing"))
+	if err != nil {
+		return nil, fmt.Errorf("otel: creating jobs.failed counter: %w", err)
+	}
+	duration, err := meter.Float64Histogram("jobs.processing_duration_seconds",
+		metric.WithDescription("Time spent processing a job"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, fmt.Errorf("otel: creating jobs.processing_duration_seconds histogram: %w", err)
+	}
+
+	return &JobMetrics{
+		Enqueued:  enqueued,
+		Processed: processed,
+		Failed:    failed,
+		Duration:  duration,
+	}, nil
+}
+
+// InjectTraceParent returns the W3C traceparent header describing the
+// span active in ctx, or "" if ctx carries no span.
+func InjectTraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	return carrier.Get("traceparent")
+}
+
+// ExtractContext returns a context carrying the span described by
+// traceParent, so spans started from it join that trace. An empty
+// traceParent returns ctx unchanged.
+func ExtractContext(ctx context.Context, traceParent string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+	carrier := propagation.MapCarrier{"traceparent": traceParent}
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
@@ -0,0 +1,220 @@
+// Package queue provides a reliable, Redis-backed job queue shared by the
+// api and worker binaries. Jobs are pushed onto a pending list and moved
+// atomically into a processing list while a worker handles them, so a
+// crashed worker leaves the job recoverable instead of lost.
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Default list keys used when a Queue is constructed with New.
+const (
+	DefaultPendingKey    = "jobs:pending"
+	DefaultProcessingKey = "jobs:processing"
+)
+
+// MaxRetries is the number of times a failed job is requeued before it is
+// dropped.
+const MaxRetries = 3
+
+// EventsChannel is the Redis pub/sub channel lifecycle Events are
+// published to.
+const EventsChannel = "jobs:events"
+
+// EventType identifies a point in a job's lifecycle.
+type EventType string
+
+// The lifecycle events a job passes through, in order (Failed may be
+// followed by another Queued if the job is retried).
+const (
+	EventQueued    EventType = "queued"
+	EventStarted   EventType = "started"
+	EventSucceeded EventType = "succeeded"
+	EventFailed    EventType = "failed"
+)
+
+// Event is a lifecycle notification published to EventsChannel.
+type Event struct {
+	JobID string    `json:"job_id"`
+	Type  EventType `json:"type"`
+	Error string    `json:"error,omitempty"`
+}
+
+// Job is the unit of work passed through the queue.
+type Job struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	Retries   int             `json:"retries"`
+	CreatedAt time.Time       `json:"created_at"`
+
+	// TraceParent is the W3C traceparent header of the trace that
+	// enqueued this job, if any, so a worker can continue that trace
+	// instead of starting a new one.
+	TraceParent string `json:"traceparent,omitempty"`
+}
+
+// Queue pushes and pops Jobs against a Redis list pair.
+type Queue struct {
+	client        *redis.Client
+	pendingKey    string
+	processingKey string
+}
+
+// New returns a Queue using the default pending/processing list keys.
+func New(client *redis.Client) *Queue {
+	return &Queue{
+		client:        client,
+		pendingKey:    DefaultPendingKey,
+		processingKey: DefaultProcessingKey,
+	}
+}
+
+// NewClient builds a pooled Redis client from a REDIS_URL-style connection
+// string, e.g. "redis://localhost:6379/0".
+func NewClient(redisURL string) (*redis.Client, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("queue: parsing redis url: %w", err)
+	}
+	return redis.NewClient(opts), nil
+}
+
+// Enqueue assigns the job a UUID and pushes it onto the pending list.
+func (q *Queue) Enqueue(ctx context.Context, jobType string, payload json.RawMessage) (*Job, error) {
+	return q.EnqueueWithTraceParent(ctx, jobType, payload, "")
+}
+
+// EnqueueWithTraceParent behaves like Enqueue but also stores a W3C
+// traceparent header on the job, so a worker processing it can continue
+// the trace that enqueued it.
+func (q *Queue) EnqueueWithTraceParent(ctx context.Context, jobType string, payload json.RawMessage, traceParent string) (*Job, error) {
+	job := &Job{
+		ID:          uuid.NewString(),
+		Type:        jobType,
+		Payload:     payload,
+		CreatedAt:   time.Now().UTC(),
+		TraceParent: traceParent,
+	}
+	if err := q.push(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Pop blocks until a job is available or timeout elapses, moving it
+// atomically from the pending list to the processing list so it is not
+// lost if the caller dies before Ack or Requeue is called. A nil Job with
+// a nil error means the timeout elapsed with nothing to do.
+func (q *Queue) Pop(ctx context.Context, timeout time.Duration) (*Job, error) {
+	data, err := q.client.BLMove(ctx, q.pendingKey, q.processingKey, "RIGHT", "LEFT", timeout).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("queue: popping job: %w", err)
+	}
+	var job Job
+	if err := json.Unmarshal([]byte(data), &job); err != nil {
+		return nil, fmt.Errorf("queue: unmarshaling job: %w", err)
+	}
+	return &job, nil
+}
+
+// Ack removes a successfully processed job from the processing list.
+func (q *Queue) Ack(ctx context.Context, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("queue: marshaling job %s: %w", job.ID, err)
+	}
+	if err := q.client.LRem(ctx, q.processingKey, 1, data).Err(); err != nil {
+		return fmt.Errorf("queue: acking job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+// Requeue removes a failed job from the processing list and, unless it has
+// exhausted MaxRetries, pushes it back onto the pending list with its
+// retry counter incremented. A job that has exhausted its retries is
+// dropped rather than retried further.
+func (q *Queue) Requeue(ctx context.Context, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("queue: marshaling job %s: %w", job.ID, err)
+	}
+	if err := q.client.LRem(ctx, q.processingKey, 1, data).Err(); err != nil {
+		return fmt.Errorf("queue: removing job %s from processing: %w", job.ID, err)
+	}
+	if job.Retries >= MaxRetries {
+		return nil
+	}
+	job.Retries++
+	return q.push(ctx, job)
+}
+
+// Publish broadcasts a lifecycle event over EventsChannel.
+func (q *Queue) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("queue: marshaling event for job %s: %w", event.JobID, err)
+	}
+	if err := q.client.Publish(ctx, EventsChannel, data).Err(); err != nil {
+		return fmt.Errorf("queue: publishing event for job %s: %w", event.JobID, err)
+	}
+	return nil
+}
+
+// Subscribe opens a dedicated connection for EventsChannel rather than
+// drawing one from the shared pool, since a pub/sub subscription would
+// otherwise hold a pooled connection for as long as it's open. It returns
+// a channel of decoded Events and a close function the caller must invoke
+// once done; the event channel is also closed when ctx is cancelled.
+func (q *Queue) Subscribe(ctx context.Context) (<-chan Event, func() error) {
+	pubsub := q.client.PSubscribe(ctx, EventsChannel)
+	events := make(chan Event)
+
+	go func() {
+		defer close(events)
+		msgs := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					return
+				}
+				var event Event
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					continue
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, pubsub.Close
+}
+
+func (q *Queue) push(ctx context.Context, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("queue: marshaling job %s: %w", job.ID, err)
+	}
+	if err := q.client.LPush(ctx, q.pendingKey, data).Err(); err != nil {
+		return fmt.Errorf("queue: pushing job %s: %w", job.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/jamie-at-bunny/mc-go-test/internal/testredis"
+)
+
+func newTestQueue(t *testing.T) *Queue {
+	t.Helper()
+	addr := testredis.Start(t)
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() { client.Close() })
+	return New(client)
+}
+
+func TestPublishSubscribeRoundTrip(t *testing.T) {
+	q := newTestQueue(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, closeSub := q.Subscribe(ctx)
+	defer closeSub()
+
+	// Give the subscription a moment to register before publishing, since
+	// PSubscribe confirmation happens asynchronously over the connection.
+	time.Sleep(50 * time.Millisecond)
+
+	want := Event{JobID: "job-1", Type: EventStarted}
+	if err := q.Publish(ctx, want); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	select {
+	case got := <-events:
+		if got != want {
+			t.Fatalf("got event %+v, want %+v", got, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for published event")
+	}
+}
+
+func TestRequeueIncrementsRetriesAndDropsAfterMaxRetries(t *testing.T) {
+	q := newTestQueue(t)
+	ctx := context.Background()
+
+	job, err := q.Enqueue(ctx, "test", nil)
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	popped, err := q.Pop(ctx, time.Second)
+	if err != nil {
+		t.Fatalf("Pop: %v", err)
+	}
+	if popped == nil || popped.ID != job.ID {
+		t.Fatalf("Pop returned %+v, want job %s", popped, job.ID)
+	}
+
+	for i := 0; i < MaxRetries; i++ {
+		if err := q.Requeue(ctx, popped); err != nil {
+			t.Fatalf("Requeue %d: %v", i, err)
+		}
+		popped, err = q.Pop(ctx, time.Second)
+		if err != nil {
+			t.Fatalf("Pop after requeue %d: %v", i, err)
+		}
+		if popped == nil {
+			t.Fatalf("Pop after requeue %d returned nil, want job", i)
+		}
+		if popped.Retries != i+1 {
+			t.Fatalf("job retries = %d, want %d", popped.Retries, i+1)
+		}
+	}
+
+	if err := q.Requeue(ctx, popped); err != nil {
+		t.Fatalf("final Requeue: %v", err)
+	}
+	dropped, err := q.Pop(ctx, 200*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Pop after exhausting retries: %v", err)
+	}
+	if dropped != nil {
+		t.Fatalf("Pop after exhausting retries returned %+v, want nil", dropped)
+	}
+}
+
+func TestEnqueueAssignsUniqueID(t *testing.T) {
+	q := newTestQueue(t)
+	ctx := context.Background()
+
+	a, err := q.Enqueue(ctx, "test", nil)
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	b, err := q.Enqueue(ctx, "test", nil)
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if a.ID == b.ID {
+		t.Fatalf("Enqueue assigned the same ID twice: %s", a.ID)
+	}
+}
@@ -0,0 +1,149 @@
+// Package ratelimit implements a Redis-backed sliding-window-log rate
+// limiter as an HTTP middleware, suitable for protecting endpoints shared
+// across many clients.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultKeyPrefix namespaces rate-limit keys in Redis.
+const DefaultKeyPrefix = "rl:"
+
+// slidingWindowScript implements the sliding-window-log algorithm
+// atomically: it evicts entries older than the window, counts what
+// remains, and admits the request only if that count is under the limit.
+// Each admitted entry's member is "now-member" rather than bare now, so
+// concurrent requests landing in the same millisecond each get their own
+// ZSET entry instead of colliding on ZADD.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+local member = ARGV[4]
+
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+local count = redis.call('ZCARD', key)
+
+if count >= limit then
+	return {0, 0, now + window}
+end
+
+redis.call('ZADD', key, now, now .. '-' .. member)
+redis.call('PEXPIRE', key, window)
+return {1, limit - count - 1, now + window}
+`)
+
+// Result describes the outcome of a rate-limit check.
+type Result struct {
+	Allowed   bool
+	Remaining int
+	ResetAt   time.Time
+}
+
+// Limiter enforces a sliding-window-log rate limit against Redis.
+type Limiter struct {
+	Client *redis.Client
+	Limit  int
+	Window time.Duration
+
+	// FailOpen controls behavior when Redis is unreachable: true allows
+	// the request through (fail-open), false rejects it with 503
+	// (fail-closed).
+	FailOpen bool
+}
+
+// New returns a Limiter admitting up to limit requests per window.
+func New(client *redis.Client, limit int, window time.Duration, failOpen bool) *Limiter {
+	return &Limiter{Client: client, Limit: limit, Window: window, FailOpen: failOpen}
+}
+
+// Allow checks and records a single request against key.
+func (l *Limiter) Allow(ctx context.Context, key string) (Result, error) {
+	now := time.Now()
+
+	res, err := slidingWindowScript.Run(ctx, l.Client, []string{key},
+		now.UnixMilli(), l.Window.Milliseconds(), l.Limit, uuid.NewString()).Result()
+	if err != nil {
+		return Result{}, fmt.Errorf("ratelimit: running script: %w", err)
+	}
+
+	vals, ok := res.([]interface{})
+	if !ok || len(vals) != 3 {
+		return Result{}, fmt.Errorf("ratelimit: unexpected script result %v", res)
+	}
+	allowed, _ := vals[0].(int64)
+	remaining, _ := vals[1].(int64)
+	resetMs, _ := vals[2].(int64)
+
+	return Result{
+		Allowed:   allowed == 1,
+		Remaining: int(remaining),
+		ResetAt:   time.UnixMilli(resetMs),
+	}, nil
+}
+
+// Middleware wraps next, enforcing the limiter against the key keyFunc
+// derives from each request (typically the client IP or an API key).
+func (l *Limiter) Middleware(keyFunc func(*http.Request) string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result, err := l.Allow(r.Context(), DefaultKeyPrefix+keyFunc(r))
+		if err != nil {
+			log.Printf("ratelimit: %v", err)
+			if !l.FailOpen {
+				http.Error(w, "rate limiter unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(result.ResetAt.Unix(), 10))
+
+		if !result.Allowed {
+			retryAfter := time.Until(result.ResetAt)
+			if retryAfter < 0 {
+				retryAfter = 0
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// KeyByIP derives a rate-limit key from the request's remote IP. It
+// deliberately ignores X-Forwarded-For: without a configured trusted-proxy
+// hop count there's no way to tell a real proxy's header from one forged by
+// the client, and trusting it blindly would let a client mint a fresh
+// rate-limit bucket on every request.
+func KeyByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// KeyByAPIKeyOrIP derives a rate-limit key from the X-API-Key header when
+// present, falling back to KeyByIP otherwise, so authenticated callers get
+// their own bucket independent of any shared egress IP.
+func KeyByAPIKeyOrIP(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return "key:" + key
+	}
+	return "ip:" + KeyByIP(r)
+}
@@ -0,0 +1,157 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/jamie-at-bunny/mc-go-test/internal/testredis"
+)
+
+func newTestClient(t *testing.T) *redis.Client {
+	t.Helper()
+	addr := testredis.Start(t)
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestLimiterAllowsUpToLimitThenBlocks(t *testing.T) {
+	client := newTestClient(t)
+	limiter := New(client, 2, time.Minute, false)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		result, err := limiter.Allow(ctx, "rl:test")
+		if err != nil {
+			t.Fatalf("Allow: %v", err)
+		}
+		if !result.Allowed {
+			t.Fatalf("request %d: want allowed, got blocked", i)
+		}
+	}
+
+	result, err := limiter.Allow(ctx, "rl:test")
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	if result.Allowed {
+		t.Fatal("third request: want blocked, got allowed")
+	}
+}
+
+// TestLimiterConcurrentRequestsDoNotCollide guards against requests that
+// land in the same millisecond sharing a ZSET member and under-counting:
+// firing well more than limit concurrent requests must admit exactly limit
+// of them, never more.
+func TestLimiterConcurrentRequestsDoNotCollide(t *testing.T) {
+	client := newTestClient(t)
+	limiter := New(client, 3, time.Minute, false)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	var allowed atomic.Int64
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := limiter.Allow(ctx, "rl:concurrent")
+			if err != nil {
+				t.Errorf("Allow: %v", err)
+				return
+			}
+			if result.Allowed {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := allowed.Load(); got != 3 {
+		t.Fatalf("allowed = %d, want 3", got)
+	}
+}
+
+func TestMiddlewareSetsHeadersAndRejectsOverLimit(t *testing.T) {
+	client := newTestClient(t)
+	limiter := New(client, 1, time.Minute, false)
+	handler := limiter.Middleware(KeyByIP, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("first request: got %d, want 200", resp.StatusCode)
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		t.Fatalf("X-RateLimit-Remaining = %q, want \"0\"", resp.Header.Get("X-RateLimit-Remaining"))
+	}
+
+	resp, err = http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("second request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("second request: got %d, want 429", resp.StatusCode)
+	}
+	if resp.Header.Get("Retry-After") == "" {
+		t.Fatal("Retry-After header missing on 429 response")
+	}
+}
+
+func TestMiddlewareFailOpenOnBrokenRedis(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1"})
+	defer client.Close()
+
+	limiter := New(client, 1, time.Minute, true)
+	handler := limiter.Middleware(KeyByIP, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("fail-open request: got %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestKeyByAPIKeyOrIP(t *testing.T) {
+	withKey := httptest.NewRequest(http.MethodGet, "/", nil)
+	withKey.Header.Set("X-API-Key", "abc123")
+	if got, want := KeyByAPIKeyOrIP(withKey), "key:abc123"; got != want {
+		t.Fatalf("KeyByAPIKeyOrIP() = %q, want %q", got, want)
+	}
+
+	withoutKey := httptest.NewRequest(http.MethodGet, "/", nil)
+	withoutKey.RemoteAddr = "203.0.113.5:4321"
+	if got, want := KeyByAPIKeyOrIP(withoutKey), "ip:203.0.113.5"; got != want {
+		t.Fatalf("KeyByAPIKeyOrIP() = %q, want %q", got, want)
+	}
+
+	forwarded := httptest.NewRequest(http.MethodGet, "/", nil)
+	forwarded.RemoteAddr = "203.0.113.5:4321"
+	forwarded.Header.Set("X-Forwarded-For", "198.51.100.7, 10.0.0.1")
+	if got, want := KeyByAPIKeyOrIP(forwarded), "ip:203.0.113.5"; got != want {
+		t.Fatalf("KeyByAPIKeyOrIP() = %q, want %q, X-Forwarded-For must not override RemoteAddr", got, want)
+	}
+}
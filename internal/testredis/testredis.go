@@ -0,0 +1,89 @@
+// Package testredis starts a Redis instance for use in tests. It prefers a
+// real redis-server subprocess when one is on PATH, since that most
+// closely matches production, and falls back to an in-process miniredis
+// otherwise so tests still run in environments without a redis-server
+// binary.
+package testredis
+
+import (
+	"net"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// Start returns the address of a running Redis instance and registers its
+// teardown with t.Cleanup.
+func Start(t *testing.T) string {
+	t.Helper()
+
+	if addr, ok := startProcess(t); ok {
+		return addr
+	}
+	return startMini(t)
+}
+
+func startMini(t *testing.T) string {
+	t.Helper()
+
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("testredis: starting miniredis: %v", err)
+	}
+	t.Cleanup(mr.Close)
+	return mr.Addr()
+}
+
+func startProcess(t *testing.T) (string, bool) {
+	t.Helper()
+
+	bin, err := exec.LookPath("redis-server")
+	if err != nil {
+		return "", false
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", false
+	}
+	addr := ln.Addr().String()
+	_, port, _ := net.SplitHostPort(addr)
+	ln.Close()
+
+	cmd := exec.Command(bin,
+		"--port", port,
+		"--bind", "127.0.0.1",
+		"--save", "",
+		"--appendonly", "no",
+	)
+	if err := cmd.Start(); err != nil {
+		return "", false
+	}
+
+	if !waitUntilUp(addr, 2*time.Second) {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return "", false
+	}
+
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+	return addr, true
+}
+
+func waitUntilUp(addr string, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		conn, err := net.DialTimeout("tcp", addr, 100*time.Millisecond)
+		if err == nil {
+			conn.Close()
+			return true
+		}
+		time.Sleep(25 * time.Millisecond)
+	}
+	return false
+}
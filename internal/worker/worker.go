@@ -0,0 +1,179 @@
+// Package worker implements the job-processing loop for the worker binary
+// as a Run(ctx, cfg) function so it can be started and stopped from tests
+// as well as from cmd/worker/main.go.
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	otelpkg "github.com/jamie-at-bunny/mc-go-test/internal/otel"
+	"github.com/jamie-at-bunny/mc-go-test/internal/queue"
+)
+
+// popTimeout bounds how long BLMove blocks between checks of ctx, so
+// shutdown is prompt even when the queue is empty.
+const popTimeout = 5 * time.Second
+
+// Handlers maps a job's Type to the function that processes it. For an
+// unknown type dispatch returns an error, so the job fails. Exported so tests
+// can register handlers for job types exercised in the test harness.
+var Handlers = map[string]func(*queue.Job) error{
+	"noop": func(job *queue.Job) error {
+		log.Printf("Worker: noop job %s", job.ID)
+		return nil
+	},
+}
+
+// Config holds the environment-derived settings the worker needs to run.
+type Config struct {
+	RedisURL string
+
+	// OTELEndpoint is the OTLP/gRPC endpoint traces are exported to,
+	// typically from OTEL_EXPORTER_OTLP_ENDPOINT. Empty disables trace
+	// export.
+	OTELEndpoint string
+
+	// MetricsPort is the port /metrics is served on so the worker's job
+	// throughput metrics are scrapable; the API server exposes its own
+	// /metrics separately.
+	MetricsPort string
+}
+
+// Run starts the worker loop and blocks until ctx is cancelled.
+func Run(ctx context.Context, cfg Config) error {
+	log.Printf("Worker starting (redis: %s)", cfg.RedisURL)
+
+	telemetry, err := otelpkg.Setup(ctx, otelpkg.Config{ServiceName: "mc-go-test-worker", Endpoint: cfg.OTELEndpoint})
+	if err != nil {
+		return fmt.Errorf("worker: setting up telemetry: %w", err)
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := telemetry.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Worker: telemetry shutdown: %v", err)
+		}
+	}()
+
+	jobMetrics, err := otelpkg.NewJobMetrics(telemetry.MeterProvider)
+	if err != nil {
+		return fmt.Errorf("worker: setting up job metrics: %w", err)
+	}
+	tracer := telemetry.TracerProvider.Tracer("github.com/jamie-at-bunny/mc-go-test/internal/worker")
+
+	metricsLn, err := net.Listen("tcp", ":"+cfg.MetricsPort)
+	if err != nil {
+		return fmt.Errorf("worker: listening on metrics port %s: %w", cfg.MetricsPort, err)
+	}
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", telemetry.MetricsHandler)
+	metricsSrv := &http.Server{Handler: metricsMux}
+	go func() {
+		if err := metricsSrv.Serve(metricsLn); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Worker: metrics server: %v", err)
+		}
+	}()
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := metricsSrv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Worker: metrics server shutdown: %v", err)
+		}
+	}()
+	log.Printf("Worker: serving /metrics on %s", metricsLn.Addr())
+
+	client, err := queue.NewClient(cfg.RedisURL)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if err := otelpkg.InstrumentRedis(client, telemetry.TracerProvider, telemetry.MeterProvider); err != nil {
+		return fmt.Errorf("worker: instrumenting redis client: %w", err)
+	}
+	q := queue.New(client)
+
+	for {
+		job, err := q.Pop(ctx, popTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				log.Println("Worker: shutting down")
+				return nil
+			}
+			log.Printf("Worker: pop failed: %v", err)
+			continue
+		}
+		if job == nil {
+			if ctx.Err() != nil {
+				log.Println("Worker: shutting down")
+				return nil
+			}
+			continue
+		}
+
+		processJob(ctx, q, job, tracer, jobMetrics)
+	}
+}
+
+// processJob runs one pop/process cycle for job inside a span that joins
+// the trace which enqueued it (if any), publishing lifecycle events and
+// recording throughput metrics around the handler call.
+func processJob(ctx context.Context, q *queue.Queue, job *queue.Job, tracer trace.Tracer, metrics *otelpkg.JobMetrics) {
+	jobCtx := otelpkg.ExtractContext(ctx, job.TraceParent)
+	jobCtx, span := tracer.Start(jobCtx, "job.process", trace.WithAttributes(
+		attribute.String("job.id", job.ID),
+		attribute.String("job.type", job.Type),
+	))
+	defer span.End()
+
+	publish(jobCtx, q, job.ID, queue.EventStarted, "")
+
+	start := time.Now()
+	err := dispatch(job)
+	metrics.Duration.Record(jobCtx, time.Since(start).Seconds())
+
+	if err != nil {
+		log.Printf("Worker: job %s failed: %v", job.ID, err)
+		span.RecordError(err)
+		metrics.Failed.Add(jobCtx, 1)
+		publish(jobCtx, q, job.ID, queue.EventFailed, err.Error())
+		retrying := job.Retries < queue.MaxRetries
+		if rqErr := q.Requeue(ctx, job); rqErr != nil {
+			log.Printf("Worker: requeue job %s failed: %v", job.ID, rqErr)
+		} else if retrying {
+			publish(jobCtx, q, job.ID, queue.EventQueued, "")
+		}
+		return
+	}
+
+	metrics.Processed.Add(jobCtx, 1)
+	publish(jobCtx, q, job.ID, queue.EventSucceeded, "")
+	if err := q.Ack(ctx, job); err != nil {
+		log.Printf("Worker: ack job %s failed: %v", job.ID, err)
+	}
+}
+
+// publish broadcasts a lifecycle event, logging rather than failing the
+// job if Redis is briefly unavailable for the publish itself.
+func publish(ctx context.Context, q *queue.Queue, jobID string, eventType queue.EventType, errMsg string) {
+	event := queue.Event{JobID: jobID, Type: eventType, Error: errMsg}
+	if err := q.Publish(ctx, event); err != nil {
+		log.Printf("Worker: publishing %s event for job %s: %v", eventType, jobID, err)
+	}
+}
+
+func dispatch(job *queue.Job) error {
+	handler, ok := Handlers[job.Type]
+	if !ok {
+		return fmt.Errorf("no handler registered for job type %q", job.Type)
+	}
+	return handler(job)
+}